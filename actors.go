@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+
+	"github.com/revboss/go-notify/transport"
+)
+
+// LogActor logs a received notification via the standard library logger.
+// Prefix, if set, is printed before the notification's type and version.
+type LogActor struct {
+	Prefix string
+}
+
+func (a LogActor) Act(ctx context.Context, notification *Notification) error {
+	log.Printf("%snotify: %s:%d received at %s", a.Prefix, notification.Type, notification.Version, notification.Time)
+	return nil
+}
+
+// DropActor stops a notification's pipeline by returning ErrDropped: the
+// message is acked and no further actor or handler sees it.
+type DropActor struct{}
+
+func (DropActor) Act(ctx context.Context, notification *Notification) error {
+	return ErrDropped
+}
+
+// ForwardActor re-publishes a notification onto another Transport. The
+// notification has already been through decode(), which replaces Data
+// with the concrete decoded struct, so it's re-encoded back into the
+// same base64-wire shape Send produces before publishing; otherwise a
+// decode() on the far end would find Data isn't the string it expects.
+// The receiving Notifications still needs a matching schema registered
+// via AddSchema for this Type/Version.
+type ForwardActor struct {
+	Transport transport.Transport
+}
+
+func (a ForwardActor) Act(ctx context.Context, notification *Notification) error {
+	data, err := json.Marshal(notification.Data)
+	if err != nil {
+		return err
+	}
+
+	forwarded := *notification
+	forwarded.Data = base64.StdEncoding.EncodeToString(data)
+
+	body, err := json.Marshal(forwarded)
+	if err != nil {
+		return err
+	}
+
+	return a.Transport.Publish(ctx, body, nil)
+}
@@ -0,0 +1,77 @@
+package notify_test
+
+import (
+	. "github.com/franela/goblin"
+	"github.com/revboss/go-notify"
+	"github.com/revboss/go-notify/transport"
+	"testing"
+	"time"
+)
+
+func TestForwardActor(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("ForwardActor", func() {
+		g.It("Should re-publish a notification so the far end can Receive it", func() {
+			source := notify.New(transport.NewMemory())
+			dest := notify.New(transport.NewMemory())
+
+			for _, n := range []*notify.Notifications{source, dest} {
+				e := n.AddSchema(notify.Schema{
+					Type:    "testing",
+					Version: 1,
+					Schema:  TestData{},
+				})
+				g.Assert(e).Equal(nil)
+			}
+
+			source.RegisterActor("forward", notify.ForwardActor{Transport: dest.Transport})
+			source.Rules = []notify.Rule{
+				{
+					Match:  func(*notify.Notification) bool { return true },
+					Actors: []string{"forward"},
+				},
+			}
+
+			var sourceNotification notify.Notification
+			sourceDone := make(chan error, 1)
+			go func() {
+				sourceDone <- source.Receive(&sourceNotification)
+			}()
+
+			e := source.Send(notify.Notification{
+				Type:    "testing",
+				Version: 1,
+				Data: TestData{
+					String: "string",
+					Int:    1,
+					Bool:   true,
+				},
+			})
+			g.Assert(e).Equal(nil)
+
+			select {
+			case e := <-sourceDone:
+				g.Assert(e).Equal(nil)
+			case <-time.After(2 * time.Second):
+				t.Fatal("timed out waiting for source.Receive to run the rule pipeline")
+			}
+
+			var destNotification notify.Notification
+			destDone := make(chan error, 1)
+			go func() {
+				destDone <- dest.Receive(&destNotification)
+			}()
+
+			select {
+			case e := <-destDone:
+				g.Assert(e).Equal(nil)
+			case <-time.After(2 * time.Second):
+				t.Fatal("timed out waiting for the forwarded notification")
+			}
+
+			data := destNotification.Data.(*TestData)
+			g.Assert(data.String).Equal("string")
+		})
+	})
+}
@@ -1,24 +1,48 @@
 package notify
 
-import (
-	"io"
-)
+import "io"
 
-type Buffer []byte
+// Buffer is a growable in-memory byte buffer implementing io.ReadWriter.
+// Writes append; Read advances an internal offset so sequential reads
+// consume the buffer rather than replaying it, returning io.EOF only once
+// it's exhausted.
+type Buffer struct {
+	buf []byte
+	off int
+}
 
-func (b *Buffer) Read(p []byte) (int, error) {
-	copy(p[0:], []byte(*b)[0:len(*b)])
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
 
-	if len([]byte(*b)) > len(p) {
-		return len(p), io.EOF
-	} else {
-		return len([]byte(*b)), io.EOF
+func (b *Buffer) Read(p []byte) (int, error) {
+	if b.off >= len(b.buf) {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
 	}
 
-	return 0, nil
+	n := copy(p, b.buf[b.off:])
+	b.off += n
+
+	return n, nil
 }
 
-func (b *Buffer) Write(p []byte) (int, error) {
-	*b = append(*b, p...)
-	return 0, nil
+// Len returns the number of unread bytes remaining in the buffer.
+func (b *Buffer) Len() int {
+	return len(b.buf) - b.off
+}
+
+// Reset discards all buffered data.
+func (b *Buffer) Reset() {
+	b.buf = b.buf[:0]
+	b.off = 0
+}
+
+// Bytes returns the unread portion of the buffer. The returned slice is
+// valid only until the next call to Write or Reset.
+func (b *Buffer) Bytes() []byte {
+	return b.buf[b.off:]
 }
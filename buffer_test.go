@@ -0,0 +1,70 @@
+package notify_test
+
+import (
+	. "github.com/franela/goblin"
+	"github.com/revboss/go-notify"
+	"io"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("Buffer", func() {
+		g.It("Should report the number of bytes written", func() {
+			var b notify.Buffer
+
+			n, e := b.Write([]byte("hello"))
+			g.Assert(e).Equal(nil)
+			g.Assert(n).Equal(5)
+		})
+
+		g.It("Should read back what was written", func() {
+			var b notify.Buffer
+			b.Write([]byte("hello"))
+
+			p := make([]byte, 5)
+			n, e := b.Read(p)
+			g.Assert(e).Equal(nil)
+			g.Assert(n).Equal(5)
+			g.Assert(string(p)).Equal("hello")
+		})
+
+		g.It("Should advance the read cursor across repeated reads", func() {
+			var b notify.Buffer
+			b.Write([]byte("hello world"))
+
+			first := make([]byte, 5)
+			b.Read(first)
+
+			second := make([]byte, 6)
+			n, e := b.Read(second)
+			g.Assert(e).Equal(nil)
+			g.Assert(n).Equal(6)
+			g.Assert(string(second)).Equal(" world")
+		})
+
+		g.It("Should only return io.EOF once exhausted", func() {
+			var b notify.Buffer
+			b.Write([]byte("hi"))
+
+			p := make([]byte, 2)
+			_, e := b.Read(p)
+			g.Assert(e).Equal(nil)
+
+			_, e = b.Read(p)
+			g.Assert(e).Equal(io.EOF)
+		})
+
+		g.It("Should report Len, Reset, and Bytes", func() {
+			var b notify.Buffer
+			b.Write([]byte("hello"))
+
+			g.Assert(b.Len()).Equal(5)
+			g.Assert(string(b.Bytes())).Equal("hello")
+
+			b.Reset()
+			g.Assert(b.Len()).Equal(0)
+		})
+	})
+}
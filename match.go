@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Matcher is a small expression evaluated against a notification's JSON
+// representation, letting LoadRules route on a field buried inside Data
+// (e.g. "notification.status equals failed") without a Go code change.
+type Matcher struct {
+	// Path is a dot-separated path into the notification's JSON form.
+	// Data is tagged "notification", so a field named Status on it is
+	// reached as "notification.Status", not "data.Status"; top-level
+	// envelope fields are reached directly, e.g. "type". An unresolved
+	// path makes Evaluate return false rather than error, so a typo'd
+	// path silently never matches.
+	Path string `json:"path"`
+
+	// Op is one of "eq", "ne", "contains", "gt", "lt". Defaults to "eq".
+	Op string `json:"op"`
+
+	Value interface{} `json:"value"`
+}
+
+// Evaluate reports whether notification satisfies m.
+func (m Matcher) Evaluate(notification *Notification) bool {
+	raw, err := json.Marshal(notification)
+	if err != nil {
+		return false
+	}
+
+	var generic map[string]interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return false
+	}
+
+	value, ok := lookupPath(generic, m.Path)
+	if !ok {
+		return false
+	}
+
+	switch m.Op {
+	case "", "eq":
+		return fmt.Sprint(value) == fmt.Sprint(m.Value)
+	case "ne":
+		return fmt.Sprint(value) != fmt.Sprint(m.Value)
+	case "contains":
+		s, sOk := value.(string)
+		sub, subOk := m.Value.(string)
+		return sOk && subOk && strings.Contains(s, sub)
+	case "gt", "lt":
+		v, vOk := toFloat(value)
+		want, wantOk := toFloat(m.Value)
+		if !vOk || !wantOk {
+			return false
+		}
+		if m.Op == "gt" {
+			return v > want
+		}
+		return v < want
+	default:
+		return false
+	}
+}
+
+func lookupPath(generic map[string]interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+
+	var current interface{} = generic
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
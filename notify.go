@@ -1,57 +1,93 @@
 package notify
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/sqs"
 	"reflect"
+	"sync"
 	"time"
+
+	"github.com/revboss/go-notify/transport"
 )
 
 type Schema struct {
 	Type    string      `json:"type"`
 	Version int         `json:"version"`
 	Schema  interface{} `json:"schema"`
-}
 
-type SQS interface {
-	DeleteMessage(*sqs.DeleteMessageInput) (*sqs.DeleteMessageOutput, error)
-	ReceiveMessage(*sqs.ReceiveMessageInput) (*sqs.ReceiveMessageOutput, error)
-	SendMessage(*sqs.SendMessageInput) (*sqs.SendMessageOutput, error)
+	// Document, if set, is a JSON Schema that Notification.Data is
+	// validated against on both Send and Receive/Start, before it's
+	// unmarshalled into Schema. A nil Document skips validation, same as
+	// before this field existed.
+	Document interface{} `json:"document,omitempty"`
 }
 
 type Notification struct {
 	Time    time.Time   `json:"time"`
+	At      time.Time   `json:"at,omitempty"`
 	Type    string      `json:"type"`
 	Version int         `json:"version"`
 	Data    interface{} `json:"notification"`
 }
 
-type Notifications struct {
-	SQS      SQS
-	QueueURL string
-	Rate     time.Duration
-	Schemas  map[string]map[int]Schema
+// maxNativeDelay is the largest delay Send will hand a transport via
+// PublishAt. Anything scheduled further out than this is tagged with
+// scheduledAttribute instead and left for the AtSender loop to re-enqueue
+// once it's within range.
+const maxNativeDelay = 900 * time.Second
 
-	ch chan interface{}
-}
+// scheduledAttribute marks a message that hasn't come due yet so that
+// Receive knows to put it back rather than deliver it. Transports that
+// don't understand the attribute will simply deliver the message early.
+// It's transport.ScheduledAttribute under the hood so a Scanner
+// implementation can filter on the same key without importing this
+// package.
+const scheduledAttribute = transport.ScheduledAttribute
 
-func New(sqs SQS, queue string) *Notifications {
-	notifications := &Notifications{
-		QueueURL: queue,
-		Schemas:  make(map[string]map[int]Schema),
-		Rate:     1 * time.Second,
-		SQS:      sqs,
+type Notifications struct {
+	Transport transport.Transport
+	Schemas   map[string]map[int]Schema
+
+	// MinDelay and MaxDelay bound the scheduling window accepted by
+	// Send. A zero value leaves that bound unenforced.
+	MinDelay time.Duration
+	MaxDelay time.Duration
+
+	// AtSenderInterval controls how often the AtSender loop wakes up to
+	// check for scheduled messages that have come due.
+	AtSenderInterval time.Duration
+
+	// MaxRetries and RetryBackoff govern how Start retries a handler
+	// that returns an error before giving up on a message. RetryBackoff
+	// is multiplied by the attempt number, so retries back off linearly.
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	// Rules are evaluated, in order, against every decoded notification
+	// before it's acked; the first match's Actors run against it. See
+	// RegisterActor and LoadRules.
+	Rules []Rule
+
+	ch         <-chan transport.RawMessage
+	actors     map[string]Actor
+	migrations map[migrationKey]MigrationFunc
+}
 
-		ch: make(chan interface{}),
+// New builds Notifications around any Transport, SQS included (see
+// transport.NewSQS).
+func New(t transport.Transport) *Notifications {
+	return &Notifications{
+		Transport:        t,
+		Schemas:          make(map[string]map[int]Schema),
+		AtSenderInterval: 30 * time.Second,
+		MaxRetries:       3,
+		RetryBackoff:     1 * time.Second,
 	}
-
-	return notifications
 }
 
-func (n Notifications) AddSchema(schema Schema) error {
+func (n *Notifications) AddSchema(schema Schema) error {
 	if schemas, ok := n.Schemas[schema.Type]; ok {
 		if _, ok := schemas[schema.Version]; ok {
 			return fmt.Errorf("Schema already exists: %s:%d", schema.Type, schema.Version)
@@ -65,8 +101,8 @@ func (n Notifications) AddSchema(schema Schema) error {
 	return nil
 }
 
-func (n Notifications) Receive(notification *Notification) error {
-	data, err := n.receive()
+func (n *Notifications) Receive(notification *Notification) error {
+	data, err := n.receive(context.Background())
 	if err != nil {
 		return err
 	}
@@ -75,54 +111,201 @@ func (n Notifications) Receive(notification *Notification) error {
 	return nil
 }
 
-func (n Notifications) receive() (Notification, error) {
-	var notifications *sqs.ReceiveMessageOutput
-	var err error
+func (n *Notifications) receive(ctx context.Context) (Notification, error) {
+	if err := n.ensureSubscribed(ctx); err != nil {
+		return Notification{}, err
+	}
 
 	for {
-		time.Sleep(n.Rate)
-		notifications, err = n.SQS.ReceiveMessage(&sqs.ReceiveMessageInput{
-			QueueUrl: aws.String(n.QueueURL),
-		})
+		var raw transport.RawMessage
+		select {
+		case raw = <-n.ch:
+		case <-ctx.Done():
+			return Notification{}, ctx.Err()
+		}
 
-		// HANDLE TIMEOUT ERRORS HERE SEPARATELY FROM OTHER ERRORS SINCE
-		// WE MAY SWITCH TO LONG POLLING (WHICH CAN TIMEOUT OFTEN) SO
-		// THAT WE CAN DECREASE IDLE LOOPING HERE. THE ACTION TO TAKE ON
-		// TIMEOUT IS TO CALL `continue`.
+		if n.skipNotDue(ctx, raw) {
+			continue
+		}
 
+		notification, ok, err := n.process(ctx, raw)
 		if err != nil {
 			return Notification{}, err
 		}
+		if !ok {
+			continue
+		}
 
-		//THE FOLLOWING WOULD BECOME OBSOLETE WITH LONG POLLING SINCE WE
-		//ARE GUARANTEED TO HAVE AT LEAST ONE MESSAGE.
+		if err := n.Transport.Ack(raw); err != nil {
+			return Notification{}, err
+		}
 
-		if len(notifications.Messages) == 0 {
+		return notification, nil
+	}
+}
+
+// Start spins up concurrency worker goroutines pulling from the same
+// subscription Receive uses, decoding each message and handing it to
+// handler. A message is only acked once handler returns nil; failures are
+// retried with backoff up to MaxRetries before the message is nacked for
+// good, leaving any further redelivery or dead-lettering to the
+// transport (an SQS redrive policy, for instance). Start blocks until ctx
+// is canceled.
+func (n *Notifications) Start(ctx context.Context, handler func(Notification) error, concurrency int) error {
+	if err := n.ensureSubscribed(ctx); err != nil {
+		return err
+	}
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			n.worker(ctx, handler)
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+func (n *Notifications) worker(ctx context.Context, handler func(Notification) error) {
+	for {
+		var raw transport.RawMessage
+		select {
+		case <-ctx.Done():
+			return
+		case raw = <-n.ch:
+		}
+
+		if n.skipNotDue(ctx, raw) {
 			continue
 		}
 
-		break
+		n.dispatch(ctx, raw, handler)
+	}
+}
+
+func (n *Notifications) dispatch(ctx context.Context, raw transport.RawMessage, handler func(Notification) error) {
+	notification, ok, err := n.process(ctx, raw)
+	if err != nil || !ok {
+		return
+	}
+
+	maxRetries := n.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+
+	backoff := n.RetryBackoff
+	if backoff <= 0 {
+		backoff = 1 * time.Second
+	}
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if err := handler(notification); err == nil {
+			n.Transport.Ack(raw)
+			return
+		}
+
+		if attempt < maxRetries {
+			time.Sleep(backoff * time.Duration(attempt+1))
+		}
 	}
 
-	data, err := n.handle(notifications)
+	// Retries exhausted: nack so the transport's own dead-letter
+	// handling can take over.
+	n.Transport.Nack(raw)
+}
 
+func (n *Notifications) ensureSubscribed(ctx context.Context) error {
+	if n.ch != nil {
+		return nil
+	}
+
+	ch, err := n.Transport.Subscribe(ctx)
 	if err != nil {
-		return Notification{}, err
+		return err
 	}
 
-	return data, nil
+	n.ch = ch
+	return nil
 }
 
-func (n Notifications) handle(notifications *sqs.ReceiveMessageOutput) (Notification, error) {
-	notification := Notification{}
+// notDueBackoffCap bounds how long skipNotDue waits before nacking a
+// message that isn't due yet. Against a transport that can't filter
+// scheduled messages out server-side (NATS, Memory), nacking immediately
+// would spin the receive loop in a tight Nack/redelivery cycle for the
+// entire wait; capping the wait keeps that cycle to once per cap instead
+// of once per Nack round trip, without oversleeping past ctx
+// cancellation or past a schedule that's actually close to due.
+const notDueBackoffCap = 5 * time.Second
+
+// skipNotDue reports whether raw carries a scheduledAttribute that hasn't
+// come due yet. If so, it waits out the remaining delay (capped at
+// notDueBackoffCap) before nacking it so it goes straight back for
+// another receiver (or a later call here) to pick up once AtSender has
+// re-enqueued it or its time has passed.
+func (n *Notifications) skipNotDue(ctx context.Context, raw transport.RawMessage) bool {
+	value, ok := raw.Attributes[scheduledAttribute]
+	if !ok {
+		return false
+	}
+
+	at, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil || !time.Now().Before(at) {
+		return false
+	}
+
+	wait := time.Until(at)
+	if wait > notDueBackoffCap {
+		wait = notDueBackoffCap
+	}
+
+	select {
+	case <-time.After(wait):
+	case <-ctx.Done():
+	}
+
+	n.Transport.Nack(raw)
+
+	return true
+}
+
+// process decodes raw and runs it through any matching Rule's Actors.
+// ok is false when the message was dropped by an actor (it's acked, same
+// as a successful delivery) or failed to decode or act (it's nacked,
+// along with a non-nil err); in both cases the caller should move on
+// without delivering it further.
+func (n *Notifications) process(ctx context.Context, raw transport.RawMessage) (notification Notification, ok bool, err error) {
+	notification, err = n.decode(raw)
+	if err != nil {
+		n.Transport.Nack(raw)
+		return Notification{}, false, err
+	}
+
+	if err := n.runActors(ctx, &notification); err != nil {
+		if err == ErrDropped {
+			n.Transport.Ack(raw)
+			return Notification{}, false, nil
+		}
+
+		n.Transport.Nack(raw)
+		return Notification{}, false, err
+	}
 
-	//We only handle only one message at a time all remaining messages get
-	//put back on the queue for later retrieval this can be optimized in the
-	//future (some of our queues have a max message retrieval of 1 anyway).
+	return notification, true, nil
+}
 
-	message := notifications.Messages[0]
+func (n *Notifications) decode(raw transport.RawMessage) (Notification, error) {
+	notification := Notification{}
 
-	e := json.Unmarshal([]byte(*message.Body), &notification)
+	e := json.Unmarshal(raw.Body, &notification)
 	if e != nil {
 		return Notification{}, e
 	}
@@ -137,32 +320,72 @@ func (n Notifications) handle(notifications *sqs.ReceiveMessageOutput) (Notifica
 		return Notification{}, e
 	}
 
-	re := reflect.New(reflect.TypeOf(schema.Schema)).Interface()
+	var generic interface{}
+	if e := json.Unmarshal(data, &generic); e != nil {
+		return Notification{}, e
+	}
+
+	if e := validateDocument(notification.Type, notification.Version, schema.Document, generic); e != nil {
+		return Notification{}, e
+	}
+
+	version := notification.Version
+	for target := n.currentVersion(notification.Type); version < target; version++ {
+		fn, ok := n.migrations[migrationKey{Type: notification.Type, From: version}]
+		if !ok {
+			return Notification{}, fmt.Errorf("Migration does not exist: %s:%d->%d", notification.Type, version, version+1)
+		}
+
+		generic, e = fn(generic)
+		if e != nil {
+			return Notification{}, fmt.Errorf("Migration failed: %s:%d->%d: %s", notification.Type, version, version+1, e)
+		}
+	}
+
+	current, ok := n.Schemas[notification.Type][version]
+	if !ok {
+		return Notification{}, fmt.Errorf("Schema does not exist: %s:%d", notification.Type, version)
+	}
 
-	e = json.Unmarshal(data, re)
+	migrated, e := json.Marshal(generic)
 	if e != nil {
 		return Notification{}, e
 	}
 
-	notification.Data = re
+	re := reflect.New(reflect.TypeOf(current.Schema)).Interface()
 
-	_, e = n.SQS.DeleteMessage(&sqs.DeleteMessageInput{
-		QueueUrl:      aws.String(n.QueueURL),
-		ReceiptHandle: message.ReceiptHandle,
-	})
+	e = json.Unmarshal(migrated, re)
 	if e != nil {
 		return Notification{}, e
 	}
 
+	notification.Data = re
+	notification.Version = version
+
 	return notification, nil
 }
 
-func (n Notifications) Send(notification Notification) error {
+func (n *Notifications) Send(notification Notification) error {
 	schema, ok := n.Schemas[notification.Type][notification.Version]
 	if !ok {
 		return fmt.Errorf("Schema does not exist: %s:%d", notification.Type, notification.Version)
 	}
 
+	var delay time.Duration
+	if !notification.At.IsZero() {
+		delay = time.Until(notification.At)
+		if delay < 0 {
+			delay = 0
+		}
+
+		if n.MinDelay > 0 && delay < n.MinDelay {
+			return fmt.Errorf("Scheduled delay %s is below MinDelay %s", delay, n.MinDelay)
+		}
+		if n.MaxDelay > 0 && delay > n.MaxDelay {
+			return fmt.Errorf("Scheduled delay %s is above MaxDelay %s", delay, n.MaxDelay)
+		}
+	}
+
 	notification.Time = time.Now()
 
 	nd, e := json.Marshal(notification.Data)
@@ -170,6 +393,15 @@ func (n Notifications) Send(notification Notification) error {
 		return e
 	}
 
+	var generic interface{}
+	if e := json.Unmarshal(nd, &generic); e != nil {
+		return e
+	}
+
+	if e := validateDocument(notification.Type, notification.Version, schema.Document, generic); e != nil {
+		return e
+	}
+
 	sc := schema.Schema
 
 	e = json.Unmarshal(nd, &sc)
@@ -189,10 +421,93 @@ func (n Notifications) Send(notification Notification) error {
 		return e
 	}
 
-	_, e = n.SQS.SendMessage(&sqs.SendMessageInput{
-		MessageBody: aws.String(string(body)),
-		QueueUrl:    aws.String(n.QueueURL),
-	})
+	ctx := context.Background()
+
+	if delay == 0 {
+		return n.Transport.Publish(ctx, body, nil)
+	}
+
+	if dt, ok := n.Transport.(transport.DelayedTransport); ok && delay <= maxNativeDelay {
+		return dt.PublishAt(ctx, body, nil, delay)
+	}
+
+	// The transport can't delay this long (or at all) on its own, so the
+	// message is published now carrying a marker attribute. Receive puts
+	// it back unseen until AtSender re-enqueues it closer to its due
+	// time.
+	attributes := map[string]string{
+		scheduledAttribute: notification.At.Format(time.RFC3339Nano),
+	}
+
+	return n.Transport.Publish(ctx, body, attributes)
+}
+
+// AtSender runs until ctx is canceled, waking every AtSenderInterval to
+// look for messages scheduled further out than a transport's own native
+// delay limit and re-publishing them with that native delay once they're
+// close enough to their due time to fit within it. It only does anything
+// useful against a transport.DelayedTransport that also implements
+// transport.Scanner (SQS is the case this package ships with); against
+// other transports it's a no-op.
+func (n *Notifications) AtSender(ctx context.Context) {
+	dt, ok := n.Transport.(transport.DelayedTransport)
+	if !ok {
+		return
+	}
+
+	scanner, ok := n.Transport.(transport.Scanner)
+	if !ok {
+		return
+	}
 
-	return e
+	interval := n.AtSenderInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.atSenderTick(ctx, dt, scanner)
+		}
+	}
+}
+
+func (n *Notifications) atSenderTick(ctx context.Context, dt transport.DelayedTransport, scanner transport.Scanner) {
+	messages, err := scanner.Scan(ctx, 10)
+	if err != nil {
+		return
+	}
+
+	for _, raw := range messages {
+		value, ok := raw.Attributes[scheduledAttribute]
+		if !ok {
+			continue
+		}
+
+		at, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			continue
+		}
+
+		delay := time.Until(at)
+		if delay > maxNativeDelay {
+			// Still too far out, leave it for the next tick.
+			continue
+		}
+		if delay < 0 {
+			delay = 0
+		}
+
+		if err := dt.PublishAt(ctx, raw.Body, nil, delay); err != nil {
+			continue
+		}
+
+		n.Transport.Ack(raw)
+	}
 }
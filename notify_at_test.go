@@ -0,0 +1,75 @@
+package notify_test
+
+import (
+	. "github.com/franela/goblin"
+	"github.com/revboss/go-notify"
+	"github.com/revboss/go-notify/transport"
+	"testing"
+	"time"
+)
+
+func TestNotificationAt(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("Notification.At", func() {
+		g.It("Should hold a scheduled notification back from Receive until it's due", func() {
+			n := notify.New(transport.NewMemory())
+
+			g.Assert(n.AddSchema(notify.Schema{Type: "testing", Version: 1, Schema: TestData{}})).Equal(nil)
+
+			at := time.Now().Add(150 * time.Millisecond)
+			e := n.Send(notify.Notification{
+				Type:    "testing",
+				Version: 1,
+				At:      at,
+				Data:    TestData{String: "later"},
+			})
+			g.Assert(e).Equal(nil)
+
+			received := make(chan notify.Notification, 1)
+			errs := make(chan error, 1)
+			go func() {
+				var notification notify.Notification
+				if e := n.Receive(&notification); e != nil {
+					errs <- e
+					return
+				}
+				received <- notification
+			}()
+
+			select {
+			case <-received:
+				t.Fatal("Receive delivered a scheduled notification before its At time")
+			case e := <-errs:
+				t.Fatalf("Receive failed: %s", e)
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			g.Timeout(2 * time.Second)
+			select {
+			case notification := <-received:
+				data := notification.Data.(*TestData)
+				g.Assert(data.String).Equal("later")
+			case e := <-errs:
+				t.Fatalf("Receive failed: %s", e)
+			case <-time.After(2 * time.Second):
+				t.Fatal("timed out waiting for the scheduled notification to come due")
+			}
+		})
+
+		g.It("Should reject a Send scheduled below MinDelay", func() {
+			n := notify.New(transport.NewMemory())
+			n.MinDelay = 1 * time.Hour
+
+			g.Assert(n.AddSchema(notify.Schema{Type: "testing", Version: 1, Schema: TestData{}})).Equal(nil)
+
+			e := n.Send(notify.Notification{
+				Type:    "testing",
+				Version: 1,
+				At:      time.Now().Add(1 * time.Minute),
+				Data:    TestData{String: "too soon"},
+			})
+			g.Assert(e == nil).Equal(false)
+		})
+	})
+}
@@ -0,0 +1,102 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	. "github.com/franela/goblin"
+	"github.com/revboss/go-notify"
+	"github.com/revboss/go-notify/transport"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStart(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("Start", func() {
+		g.It("Should retry a failing handler with backoff up to MaxRetries before acking", func() {
+			n := notify.New(transport.NewMemory())
+			n.MaxRetries = 2
+			n.RetryBackoff = 10 * time.Millisecond
+
+			g.Assert(n.AddSchema(notify.Schema{Type: "testing", Version: 1, Schema: TestData{}})).Equal(nil)
+
+			e := n.Send(notify.Notification{
+				Type:    "testing",
+				Version: 1,
+				Data:    TestData{String: "eventually ok"},
+			})
+			g.Assert(e).Equal(nil)
+
+			var attempts int32
+			start := time.Now()
+			var elapsed time.Duration
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				done <- n.Start(ctx, func(notify.Notification) error {
+					attempt := atomic.AddInt32(&attempts, 1)
+					if int(attempt) <= n.MaxRetries {
+						return errors.New("not yet")
+					}
+					elapsed = time.Since(start)
+					return nil
+				}, 1)
+			}()
+
+			g.Timeout(2 * time.Second)
+			for atomic.LoadInt32(&attempts) <= int32(n.MaxRetries) {
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			cancel()
+			<-done
+
+			g.Assert(atomic.LoadInt32(&attempts)).Equal(int32(n.MaxRetries + 1))
+			// dispatch sleeps RetryBackoff*1 after the first failed attempt
+			// and RetryBackoff*2 after the second, before the third succeeds.
+			g.Assert(elapsed >= n.RetryBackoff*3).Equal(true)
+		})
+
+		g.It("Should ack once the handler succeeds, without retrying further", func() {
+			n := notify.New(transport.NewMemory())
+			n.MaxRetries = 5
+			n.RetryBackoff = 5 * time.Millisecond
+
+			g.Assert(n.AddSchema(notify.Schema{Type: "testing", Version: 1, Schema: TestData{}})).Equal(nil)
+
+			e := n.Send(notify.Notification{
+				Type:    "testing",
+				Version: 1,
+				Data:    TestData{String: "ok"},
+			})
+			g.Assert(e).Equal(nil)
+
+			var attempts int32
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				done <- n.Start(ctx, func(notify.Notification) error {
+					atomic.AddInt32(&attempts, 1)
+					return nil
+				}, 1)
+			}()
+
+			g.Timeout(2 * time.Second)
+			for atomic.LoadInt32(&attempts) == 0 {
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+			<-done
+
+			g.Assert(atomic.LoadInt32(&attempts)).Equal(int32(1))
+		})
+	})
+}
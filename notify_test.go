@@ -2,13 +2,14 @@ package notify_test
 
 import (
 	. "github.com/franela/goblin"
-	"github.com/revboss/go-mock"
 	"github.com/revboss/go-notify"
+	"github.com/revboss/go-notify/transport"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
-var TestQueue = &mock.SQS{}
+var TestTransport = transport.NewMemory()
 
 type TestData struct {
 	String string
@@ -22,14 +23,14 @@ func TestNotify(t *testing.T) {
 	g.Describe("Notifications", func() {
 		g.Describe("New", func() {
 			g.It("Should create a new notify.Notifications", func() {
-				notifications := notify.New(TestQueue, "test-queue")
+				notifications := notify.New(TestTransport)
 				g.Assert(notifications != nil)
 			})
 		})
 
 		g.Describe("AddSchema", func() {
 			g.It("Should add a new schema version", func() {
-				notifications := notify.New(TestQueue, "test-queue")
+				notifications := notify.New(TestTransport)
 
 				e := notifications.AddSchema(notify.Schema{
 					Type:    "testing",
@@ -43,7 +44,7 @@ func TestNotify(t *testing.T) {
 			})
 
 			g.It("Should not be able to define a schema version twice", func() {
-				notifications := notify.New(TestQueue, "test-queue")
+				notifications := notify.New(TestTransport)
 
 				e := notifications.AddSchema(notify.Schema{
 					Type:    "testing",
@@ -67,10 +68,9 @@ func TestNotify(t *testing.T) {
 
 		g.Describe("Send", func() {
 			g.It("Should send a notification", func() {
-				count := 0
+				var count int32
 
-				notifications := notify.New(TestQueue, "test-queue")
-				notifications.Rate = 1 * time.Second
+				notifications := notify.New(TestTransport)
 
 				go func() {
 					var notification notify.Notification
@@ -83,7 +83,7 @@ func TestNotify(t *testing.T) {
 						data := notification.Data.(*TestData)
 						g.Assert(data.String).Equal("string")
 
-						count++
+						atomic.AddInt32(&count, 1)
 					}
 				}()
 
@@ -107,11 +107,11 @@ func TestNotify(t *testing.T) {
 
 				time.Sleep(2 * time.Second)
 
-				g.Assert(count > 0).IsTrue()
+				g.Assert(atomic.LoadInt32(&count) > 0).IsTrue()
 			})
 
 			g.It("Should not be able to send a notification without a schema", func() {
-				notifications := notify.New(TestQueue, "test-queue")
+				notifications := notify.New(TestTransport)
 
 				e := notifications.Send(notify.Notification{
 					Type:    "testing",
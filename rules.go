@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrDropped is returned by an Actor that wants to stop a notification's
+// pipeline without it counting as a failure: the message is acked and
+// processing moves on to the next one without delivering it to Receive's
+// caller or Start's handler.
+var ErrDropped = errors.New("notify: notification dropped")
+
+// Actor acts on a notification that a Rule matched, e.g. logging it,
+// dropping it, or forwarding it elsewhere. Built-in actors are in
+// actors.go; register any actor (built-in or user-supplied) by name with
+// RegisterActor so Rules can reference it.
+type Actor interface {
+	Act(ctx context.Context, notification *Notification) error
+}
+
+// Rule pairs a Match predicate with the names of the Actors to run when
+// it's satisfied. Notifications evaluates Rules in order and runs the
+// first match's actors in sequence, stopping at the first error.
+type Rule struct {
+	Match  func(*Notification) bool
+	Actors []string
+}
+
+// ruleConfig is the on-disk shape LoadRules reads: a Type/Version pair to
+// match on (an empty Type or zero Version matches anything), an optional
+// Matcher for reaching into the rest of the notification (including
+// Data), and the actors to run.
+type ruleConfig struct {
+	Type    string   `json:"type"`
+	Version int      `json:"version"`
+	Match   *Matcher `json:"match"`
+	Actors  []string `json:"actors"`
+}
+
+// LoadRules reads a JSON array of {type, version, match, actors} objects
+// from path and turns each into a Rule. Type and Version match as an
+// equality shortcut (an empty Type or zero Version matches anything);
+// Match, when present, is a Matcher expression evaluated against the
+// whole notification, including fields inside Data. Together they cover
+// routing that's data driven rather than requiring a Go code change;
+// callers who need anything a Matcher can't express can still build
+// Rules by hand and append to Notifications.Rules.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []ruleConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(configs))
+	for _, c := range configs {
+		c := c
+		rules = append(rules, Rule{
+			Match: func(notification *Notification) bool {
+				if c.Type != "" && notification.Type != c.Type {
+					return false
+				}
+				if c.Version != 0 && notification.Version != c.Version {
+					return false
+				}
+				if c.Match != nil && !c.Match.Evaluate(notification) {
+					return false
+				}
+				return true
+			},
+			Actors: c.Actors,
+		})
+	}
+
+	return rules, nil
+}
+
+// RegisterActor makes actor available to Rules under name.
+func (n *Notifications) RegisterActor(name string, actor Actor) {
+	if n.actors == nil {
+		n.actors = make(map[string]Actor)
+	}
+	n.actors[name] = actor
+}
+
+// runActors finds the first Rule matching notification and runs its
+// actors in order, stopping at the first error (which may be ErrDropped).
+// A notification matching no Rule passes through untouched.
+func (n *Notifications) runActors(ctx context.Context, notification *Notification) error {
+	for _, rule := range n.Rules {
+		if rule.Match == nil || !rule.Match(notification) {
+			continue
+		}
+
+		for _, name := range rule.Actors {
+			actor, ok := n.actors[name]
+			if !ok {
+				return fmt.Errorf("notify: actor not registered: %s", name)
+			}
+
+			if err := actor.Act(ctx, notification); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package notify_test
+
+import (
+	. "github.com/franela/goblin"
+	"github.com/revboss/go-notify"
+	"os"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("LoadRules", func() {
+		g.It("Should build Rules that match on Type/Version and a Matcher into Data", func() {
+			f, e := os.CreateTemp("", "rules-*.json")
+			g.Assert(e).Equal(nil)
+			defer os.Remove(f.Name())
+
+			_, e = f.WriteString(`[
+				{
+					"type": "testing",
+					"version": 1,
+					"match": {"path": "notification.String", "op": "eq", "value": "wanted"},
+					"actors": ["log"]
+				}
+			]`)
+			g.Assert(e).Equal(nil)
+			g.Assert(f.Close()).Equal(nil)
+
+			rules, e := notify.LoadRules(f.Name())
+			g.Assert(e).Equal(nil)
+			g.Assert(len(rules)).Equal(1)
+
+			wanted := &notify.Notification{
+				Type:    "testing",
+				Version: 1,
+				Data:    TestData{String: "wanted"},
+			}
+			g.Assert(rules[0].Match(wanted)).Equal(true)
+
+			unwanted := &notify.Notification{
+				Type:    "testing",
+				Version: 1,
+				Data:    TestData{String: "other"},
+			}
+			g.Assert(rules[0].Match(unwanted)).Equal(false)
+
+			wrongType := &notify.Notification{
+				Type:    "other",
+				Version: 1,
+				Data:    TestData{String: "wanted"},
+			}
+			g.Assert(rules[0].Match(wrongType)).Equal(false)
+		})
+	})
+}
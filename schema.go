@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ValidationError reports every JSON Schema violation found for a single
+// notification so callers can act on specific fields rather than parsing
+// an error string.
+type ValidationError struct {
+	Type    string
+	Version int
+	Errors  []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("notify: %s:%d failed schema validation: %s", e.Type, e.Version, strings.Join(e.Errors, "; "))
+}
+
+// validateDocument checks data against document, a JSON Schema. A nil
+// document (the common case for callers who haven't set Schema.Document)
+// is treated as "nothing to validate".
+func validateDocument(typ string, version int, document interface{}, data interface{}) error {
+	if document == nil {
+		return nil
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(document), gojsonschema.NewGoLoader(data))
+	if err != nil {
+		return err
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	errs := make([]string, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		errs = append(errs, e.String())
+	}
+
+	return &ValidationError{Type: typ, Version: version, Errors: errs}
+}
+
+// MigrationFunc upgrades a decoded payload from one schema version to the
+// next one up.
+type MigrationFunc func(old interface{}) (interface{}, error)
+
+type migrationKey struct {
+	Type string
+	From int
+}
+
+// Migrate registers fn to upgrade notifications of typ from fromVersion
+// to fromVersion+1. When Receive (or Start) sees a message older than the
+// highest version registered for typ via AddSchema, it chains migrations
+// one version at a time until it reaches it, so producers and consumers
+// can roll out schema changes independently.
+func (n *Notifications) Migrate(typ string, fromVersion int, fn MigrationFunc) error {
+	if n.migrations == nil {
+		n.migrations = make(map[migrationKey]MigrationFunc)
+	}
+
+	key := migrationKey{Type: typ, From: fromVersion}
+	if _, ok := n.migrations[key]; ok {
+		return fmt.Errorf("Migration already exists: %s:%d->%d", typ, fromVersion, fromVersion+1)
+	}
+
+	n.migrations[key] = fn
+
+	return nil
+}
+
+// currentVersion returns the highest Version registered for typ via
+// AddSchema, or 0 if none has been.
+func (n *Notifications) currentVersion(typ string) int {
+	current := 0
+	for version := range n.Schemas[typ] {
+		if version > current {
+			current = version
+		}
+	}
+	return current
+}
@@ -0,0 +1,80 @@
+package notify_test
+
+import (
+	. "github.com/franela/goblin"
+	"github.com/revboss/go-notify"
+	"github.com/revboss/go-notify/transport"
+	"testing"
+)
+
+type OldWidget struct {
+	Name string
+}
+
+type NewWidget struct {
+	FullName string
+}
+
+func TestMigrate(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("Migrate", func() {
+		g.It("Should upgrade an older version through a registered MigrationFunc on decode", func() {
+			n := notify.New(transport.NewMemory())
+
+			g.Assert(n.AddSchema(notify.Schema{Type: "widget", Version: 1, Schema: OldWidget{}})).Equal(nil)
+			g.Assert(n.AddSchema(notify.Schema{Type: "widget", Version: 2, Schema: NewWidget{}})).Equal(nil)
+
+			e := n.Migrate("widget", 1, func(old interface{}) (interface{}, error) {
+				m := old.(map[string]interface{})
+				return map[string]interface{}{"FullName": m["Name"]}, nil
+			})
+			g.Assert(e).Equal(nil)
+
+			e = n.Send(notify.Notification{
+				Type:    "widget",
+				Version: 1,
+				Data:    OldWidget{Name: "gizmo"},
+			})
+			g.Assert(e).Equal(nil)
+
+			var received notify.Notification
+			e = n.Receive(&received)
+			g.Assert(e).Equal(nil)
+			g.Assert(received.Version).Equal(2)
+
+			data := received.Data.(*NewWidget)
+			g.Assert(data.FullName).Equal("gizmo")
+		})
+	})
+
+	g.Describe("Schema.Document", func() {
+		g.It("Should reject a Send whose Data fails the JSON Schema document", func() {
+			n := notify.New(transport.NewMemory())
+
+			e := n.AddSchema(notify.Schema{
+				Type:    "widget",
+				Version: 1,
+				Schema:  OldWidget{},
+				Document: map[string]interface{}{
+					"type":     "object",
+					"required": []interface{}{"Name"},
+					"properties": map[string]interface{}{
+						"Name": map[string]interface{}{"type": "string", "minLength": 1},
+					},
+				},
+			})
+			g.Assert(e).Equal(nil)
+
+			e = n.Send(notify.Notification{
+				Type:    "widget",
+				Version: 1,
+				Data:    OldWidget{Name: ""},
+			})
+			g.Assert(e == nil).Equal(false)
+
+			_, ok := e.(*notify.ValidationError)
+			g.Assert(ok).Equal(true)
+		})
+	})
+}
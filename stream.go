@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/revboss/go-notify/transport"
+)
+
+// maxMessageBytes is the largest body a transport message is assumed to
+// tolerate (SQS's own limit). SendReader chunks a payload to stay under
+// it once base64 encoding and the envelope around each chunk are
+// accounted for.
+const maxMessageBytes = 256 * 1024
+
+// streamEnvelopeOverhead is a conservative reservation for the JSON
+// envelope wrapped around each chunk (timestamp, type, group/chunk
+// metadata), so a chunk's encoded size plus this overhead stays under
+// maxMessageBytes.
+const streamEnvelopeOverhead = 2048
+
+// maxChunkBytes is the largest raw (pre-base64) slice SendReader packs
+// into a single chunk.
+const maxChunkBytes = (maxMessageBytes - streamEnvelopeOverhead) / 4 * 3
+
+// streamChunk is the wire format SendReader/ReceiveReader use to carry a
+// piece of a larger payload. GroupID ties chunks from the same SendReader
+// call together; Index/Total let the receive side reassemble them in
+// order regardless of delivery order.
+type streamChunk struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Version int       `json:"version"`
+	GroupID string    `json:"groupId"`
+	Index   int       `json:"index"`
+	Total   int       `json:"total"`
+	Data    string    `json:"data"`
+}
+
+// SendReader streams the contents of r through typ/version, chunking it
+// across multiple transport messages when it would exceed a single
+// message's size limit. It's meant for payloads too large to fit
+// comfortably as a Notification.Data value, bypassing schema validation
+// entirely. Pair it with ReceiveReader on the receiving end, ideally over
+// a transport/queue dedicated to streamed payloads so chunks don't mix
+// with Receive/Start's typed notification traffic.
+func (n *Notifications) SendReader(typ string, version int, r io.Reader) error {
+	var buf Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return err
+	}
+
+	groupID, err := newGroupID()
+	if err != nil {
+		return err
+	}
+
+	total := (buf.Len() + maxChunkBytes - 1) / maxChunkBytes
+	if total == 0 {
+		total = 1
+	}
+
+	ctx := context.Background()
+
+	for index := 0; index < total; index++ {
+		size := maxChunkBytes
+		if buf.Len() < size {
+			size = buf.Len()
+		}
+
+		piece := make([]byte, size)
+		read, err := buf.Read(piece)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		piece = piece[:read]
+
+		body, err := json.Marshal(streamChunk{
+			Time:    time.Now(),
+			Type:    typ,
+			Version: version,
+			GroupID: groupID,
+			Index:   index,
+			Total:   total,
+			Data:    base64.StdEncoding.EncodeToString(piece),
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := n.Transport.Publish(ctx, body, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReceiveReader blocks until all of a streamed payload (as sent by
+// SendReader) has arrived, reassembles it in order regardless of the
+// order its chunks were delivered in, and returns it along with the
+// type/version SendReader was called with.
+func (n *Notifications) ReceiveReader(ctx context.Context) (typ string, version int, data []byte, err error) {
+	ch, err := n.Transport.Subscribe(ctx)
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	groups := make(map[string]map[int][]byte)
+
+	for {
+		var raw transport.RawMessage
+		select {
+		case raw = <-ch:
+		case <-ctx.Done():
+			return "", 0, nil, ctx.Err()
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal(raw.Body, &chunk); err != nil {
+			n.Transport.Nack(raw)
+			continue
+		}
+
+		piece, err := base64.StdEncoding.DecodeString(chunk.Data)
+		if err != nil {
+			n.Transport.Nack(raw)
+			continue
+		}
+
+		pieces, ok := groups[chunk.GroupID]
+		if !ok {
+			pieces = make(map[int][]byte, chunk.Total)
+			groups[chunk.GroupID] = pieces
+		}
+		pieces[chunk.Index] = piece
+
+		n.Transport.Ack(raw)
+
+		if len(pieces) != chunk.Total {
+			continue
+		}
+
+		var combined Buffer
+		for i := 0; i < chunk.Total; i++ {
+			combined.Write(pieces[i])
+		}
+
+		return chunk.Type, chunk.Version, combined.Bytes(), nil
+	}
+}
+
+func newGroupID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
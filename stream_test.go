@@ -0,0 +1,35 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	. "github.com/franela/goblin"
+	"github.com/revboss/go-notify"
+	"github.com/revboss/go-notify/transport"
+	"testing"
+	"time"
+)
+
+func TestSendReceiveReader(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("SendReader/ReceiveReader", func() {
+		g.It("Should stream a payload and reassemble it in order on the receiving end", func() {
+			n := notify.New(transport.NewMemory())
+
+			payload := bytes.Repeat([]byte("stream-me "), 20000)
+
+			e := n.SendReader("blob", 1, bytes.NewReader(payload))
+			g.Assert(e).Equal(nil)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			typ, version, data, e := n.ReceiveReader(ctx)
+			g.Assert(e).Equal(nil)
+			g.Assert(typ).Equal("blob")
+			g.Assert(version).Equal(1)
+			g.Assert(bytes.Equal(data, payload)).Equal(true)
+		})
+	})
+}
@@ -0,0 +1,54 @@
+package transport
+
+import (
+	"context"
+)
+
+// MemoryTransport is an in-process Transport suitable for tests. It
+// replaces the need for a separate mock SQS package: Publish delivers
+// straight onto the channel handed back by Subscribe, and Nack puts a
+// message back on the queue for redelivery.
+type MemoryTransport struct {
+	ch chan RawMessage
+}
+
+// NewMemory returns a Transport that holds messages in memory. It does
+// not implement Scanner, so AtSender is a no-op against it; a scheduled
+// message Send publishes here is still held back by Receive/Start, which
+// nack it until its At time has passed.
+func NewMemory() *MemoryTransport {
+	return &MemoryTransport{
+		ch: make(chan RawMessage, 64),
+	}
+}
+
+func (t *MemoryTransport) Publish(ctx context.Context, body []byte, attributes map[string]string) error {
+	msg := RawMessage{
+		Body:       append([]byte(nil), body...),
+		Attributes: attributes,
+	}
+
+	select {
+	case t.ch <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *MemoryTransport) Subscribe(ctx context.Context) (<-chan RawMessage, error) {
+	return t.ch, nil
+}
+
+func (t *MemoryTransport) Ack(message RawMessage) error {
+	return nil
+}
+
+func (t *MemoryTransport) Nack(message RawMessage) error {
+	select {
+	case t.ch <- message:
+	default:
+		go func() { t.ch <- message }()
+	}
+	return nil
+}
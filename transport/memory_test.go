@@ -0,0 +1,69 @@
+package transport_test
+
+import (
+	"context"
+	. "github.com/franela/goblin"
+	"github.com/revboss/go-notify/transport"
+	"testing"
+	"time"
+)
+
+func TestMemoryTransport(t *testing.T) {
+	g := Goblin(t)
+
+	g.Describe("MemoryTransport", func() {
+		g.It("Should deliver a Publish to Subscribe along with its attributes", func() {
+			tr := transport.NewMemory()
+
+			ch, e := tr.Subscribe(context.Background())
+			g.Assert(e).Equal(nil)
+
+			e = tr.Publish(context.Background(), []byte("hello"), map[string]string{"k": "v"})
+			g.Assert(e).Equal(nil)
+
+			g.Timeout(2 * time.Second)
+			msg := <-ch
+			g.Assert(string(msg.Body)).Equal("hello")
+			g.Assert(msg.Attributes["k"]).Equal("v")
+		})
+
+		g.It("Should requeue a Nack'd message for redelivery", func() {
+			tr := transport.NewMemory()
+
+			ch, e := tr.Subscribe(context.Background())
+			g.Assert(e).Equal(nil)
+
+			e = tr.Publish(context.Background(), []byte("retry-me"), nil)
+			g.Assert(e).Equal(nil)
+
+			g.Timeout(2 * time.Second)
+			first := <-ch
+			g.Assert(string(first.Body)).Equal("retry-me")
+
+			g.Assert(tr.Nack(first)).Equal(nil)
+
+			second := <-ch
+			g.Assert(string(second.Body)).Equal("retry-me")
+		})
+
+		g.It("Should not redeliver an Ack'd message", func() {
+			tr := transport.NewMemory()
+
+			ch, e := tr.Subscribe(context.Background())
+			g.Assert(e).Equal(nil)
+
+			e = tr.Publish(context.Background(), []byte("once"), nil)
+			g.Assert(e).Equal(nil)
+
+			g.Timeout(2 * time.Second)
+			msg := <-ch
+			g.Assert(tr.Ack(msg)).Equal(nil)
+
+			select {
+			case <-ch:
+				t.Fatal("Ack'd message was redelivered")
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package transport
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSTransport publishes and subscribes through NATS JetStream. A durable
+// pull consumer gives at-least-once delivery: messages are only removed
+// from the stream once Ack is called, and an unacked or Nack'd message is
+// redelivered.
+type NATSTransport struct {
+	JS      nats.JetStreamContext
+	Subject string
+	Durable string
+
+	// FetchWait bounds how long a single Subscribe poll waits for new
+	// messages before looping again to check ctx.
+	FetchWait time.Duration
+}
+
+// NewNATS returns a Transport backed by a JetStream subject, consumed
+// through the named durable consumer.
+func NewNATS(js nats.JetStreamContext, subject, durable string) *NATSTransport {
+	return &NATSTransport{
+		JS:        js,
+		Subject:   subject,
+		Durable:   durable,
+		FetchWait: 5 * time.Second,
+	}
+}
+
+func (t *NATSTransport) Publish(ctx context.Context, body []byte, attributes map[string]string) error {
+	msg := nats.NewMsg(t.Subject)
+	msg.Data = body
+	for k, v := range attributes {
+		msg.Header.Set(k, v)
+	}
+
+	_, err := t.JS.PublishMsg(msg)
+	return err
+}
+
+func (t *NATSTransport) Subscribe(ctx context.Context) (<-chan RawMessage, error) {
+	sub, err := t.JS.PullSubscribe(t.Subject, t.Durable)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan RawMessage)
+
+	go func() {
+		defer close(ch)
+		defer sub.Unsubscribe()
+
+		wait := t.FetchWait
+		if wait <= 0 {
+			wait = 5 * time.Second
+		}
+
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+
+			msgs, err := sub.Fetch(10, nats.MaxWait(wait))
+			if err != nil {
+				// Typically nats.ErrTimeout when nothing was
+				// published within wait; just poll again.
+				continue
+			}
+
+			for _, msg := range msgs {
+				raw := RawMessage{
+					Body:       msg.Data,
+					Attributes: fromNATSHeader(msg.Header),
+					handle:     msg,
+				}
+
+				select {
+				case ch <- raw:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (t *NATSTransport) Ack(message RawMessage) error {
+	msg, ok := message.handle.(*nats.Msg)
+	if !ok {
+		return nil
+	}
+	return msg.Ack()
+}
+
+func (t *NATSTransport) Nack(message RawMessage) error {
+	msg, ok := message.handle.(*nats.Msg)
+	if !ok {
+		return nil
+	}
+	return msg.Nak()
+}
+
+func fromNATSHeader(header nats.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+
+	out := make(map[string]string, len(header))
+	for k := range header {
+		out[k] = header.Get(k)
+	}
+	return out
+}
@@ -0,0 +1,253 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+// SQSClient is the subset of the AWS SQS client this transport needs. It
+// exists so tests can supply a fake in place of a real AWS client.
+type SQSClient interface {
+	ChangeMessageVisibility(*sqs.ChangeMessageVisibilityInput) (*sqs.ChangeMessageVisibilityOutput, error)
+	DeleteMessage(*sqs.DeleteMessageInput) (*sqs.DeleteMessageOutput, error)
+	ReceiveMessage(*sqs.ReceiveMessageInput) (*sqs.ReceiveMessageOutput, error)
+	SendMessage(*sqs.SendMessageInput) (*sqs.SendMessageOutput, error)
+}
+
+// maxDelaySeconds is the largest delay SQS will honor via DelaySeconds on
+// a SendMessage call.
+const maxDelaySeconds = 900
+
+// maxWaitTimeSeconds is the largest WaitTimeSeconds SQS accepts on a
+// ReceiveMessage call.
+const maxWaitTimeSeconds = 20
+
+// maxNumberOfMessages is the largest MaxNumberOfMessages SQS accepts on a
+// ReceiveMessage call.
+const maxNumberOfMessages = 10
+
+// receiveErrorBackoff is how long Subscribe waits after a failed
+// ReceiveMessage call before retrying, so a persistent error (bad
+// credentials, a throttled queue, network trouble) doesn't turn into a
+// busy loop against the SQS API.
+const receiveErrorBackoff = 1 * time.Second
+
+// SQSTransport is the original backend this package shipped with, now
+// expressed in terms of Transport.
+type SQSTransport struct {
+	Client   SQSClient
+	QueueURL string
+
+	// WaitTimeSeconds sets the SQS long-polling wait in Subscribe, capped
+	// at 20 (SQS's own limit). Zero or less also means 20, so Subscribe
+	// always long polls.
+	WaitTimeSeconds int64
+
+	// MaxMessages bounds how many messages a single ReceiveMessage call
+	// in Subscribe pulls at once. Defaults to 10, SQS's own limit.
+	MaxMessages int64
+}
+
+// NewSQS returns a Transport backed by an SQS queue, with long polling
+// turned on at SQS's own 20s maximum so Subscribe doesn't busy-loop
+// against the API when the queue is idle.
+func NewSQS(client SQSClient, queueURL string) *SQSTransport {
+	return &SQSTransport{
+		Client:          client,
+		QueueURL:        queueURL,
+		WaitTimeSeconds: maxWaitTimeSeconds,
+		MaxMessages:     maxNumberOfMessages,
+	}
+}
+
+func (t *SQSTransport) Publish(ctx context.Context, body []byte, attributes map[string]string) error {
+	_, err := t.Client.SendMessage(&sqs.SendMessageInput{
+		MessageBody:       aws.String(string(body)),
+		QueueUrl:          aws.String(t.QueueURL),
+		MessageAttributes: toSQSAttributes(attributes),
+	})
+	return err
+}
+
+func (t *SQSTransport) PublishAt(ctx context.Context, body []byte, attributes map[string]string, delay time.Duration) error {
+	if delay > maxDelaySeconds*time.Second {
+		return fmt.Errorf("transport: delay %s exceeds SQS's %ds limit", delay, maxDelaySeconds)
+	}
+	if delay < 0 {
+		delay = 0
+	}
+
+	_, err := t.Client.SendMessage(&sqs.SendMessageInput{
+		MessageBody:       aws.String(string(body)),
+		QueueUrl:          aws.String(t.QueueURL),
+		MessageAttributes: toSQSAttributes(attributes),
+		DelaySeconds:      aws.Int64(int64(delay.Seconds())),
+	})
+	return err
+}
+
+func (t *SQSTransport) Subscribe(ctx context.Context) (<-chan RawMessage, error) {
+	ch := make(chan RawMessage)
+
+	waitTimeSeconds := t.WaitTimeSeconds
+	if waitTimeSeconds <= 0 {
+		waitTimeSeconds = maxWaitTimeSeconds
+	}
+	if waitTimeSeconds > maxWaitTimeSeconds {
+		waitTimeSeconds = maxWaitTimeSeconds
+	}
+
+	maxMessages := t.MaxMessages
+	if maxMessages <= 0 {
+		maxMessages = maxNumberOfMessages
+	}
+
+	go func() {
+		defer close(ch)
+
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+
+			out, err := t.Client.ReceiveMessage(&sqs.ReceiveMessageInput{
+				QueueUrl:              aws.String(t.QueueURL),
+				MessageAttributeNames: []*string{aws.String("All")},
+				WaitTimeSeconds:       aws.Int64(waitTimeSeconds),
+				MaxNumberOfMessages:   aws.Int64(maxMessages),
+			})
+			if err != nil {
+				select {
+				case <-time.After(receiveErrorBackoff):
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+			if out == nil {
+				continue
+			}
+
+			for _, message := range out.Messages {
+				raw := RawMessage{
+					Body:       []byte(aws.StringValue(message.Body)),
+					Attributes: fromSQSAttributes(message.MessageAttributes),
+					handle:     aws.StringValue(message.ReceiptHandle),
+				}
+
+				select {
+				case ch <- raw:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (t *SQSTransport) Ack(message RawMessage) error {
+	handle, _ := message.handle.(string)
+	if handle == "" {
+		return nil
+	}
+
+	_, err := t.Client.DeleteMessage(&sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(t.QueueURL),
+		ReceiptHandle: aws.String(handle),
+	})
+	return err
+}
+
+func (t *SQSTransport) Nack(message RawMessage) error {
+	handle, _ := message.handle.(string)
+	if handle == "" {
+		return nil
+	}
+
+	_, err := t.Client.ChangeMessageVisibility(&sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(t.QueueURL),
+		ReceiptHandle:     aws.String(handle),
+		VisibilityTimeout: aws.Int64(0),
+	})
+	return err
+}
+
+// scanVisibilityTimeout is how long Scan holds a message invisible to
+// other consumers. AtSender is Scan's only caller and only cares about
+// messages carrying ScheduledAttribute, so this stays short: anything
+// else Scan happens to pull back is released immediately (see below)
+// rather than left to sit out this timeout, but a short one is kept as a
+// backstop in case that release itself fails.
+const scanVisibilityTimeout = 2
+
+// Scan implements Scanner by issuing a single ReceiveMessage call. Plain
+// SQS has no server-side way to filter ReceiveMessage by attribute, so
+// Scan does it client-side: any message it pulls back that isn't tagged
+// with ScheduledAttribute isn't its concern and is released right away
+// (visibility reset to 0) instead of sitting invisible to ordinary
+// consumers for scanVisibilityTimeout.
+func (t *SQSTransport) Scan(ctx context.Context, max int) ([]RawMessage, error) {
+	out, err := t.Client.ReceiveMessage(&sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(t.QueueURL),
+		MessageAttributeNames: []*string{aws.String(ScheduledAttribute)},
+		MaxNumberOfMessages:   aws.Int64(int64(max)),
+		VisibilityTimeout:     aws.Int64(scanVisibilityTimeout),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return nil, nil
+	}
+
+	messages := make([]RawMessage, 0, len(out.Messages))
+	for _, message := range out.Messages {
+		raw := RawMessage{
+			Body:       []byte(aws.StringValue(message.Body)),
+			Attributes: fromSQSAttributes(message.MessageAttributes),
+			handle:     aws.StringValue(message.ReceiptHandle),
+		}
+
+		if _, scheduled := raw.Attributes[ScheduledAttribute]; !scheduled {
+			t.Nack(raw)
+			continue
+		}
+
+		messages = append(messages, raw)
+	}
+
+	return messages, nil
+}
+
+func toSQSAttributes(attributes map[string]string) map[string]*sqs.MessageAttributeValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	out := make(map[string]*sqs.MessageAttributeValue, len(attributes))
+	for k, v := range attributes {
+		out[k] = &sqs.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(v),
+		}
+	}
+	return out
+}
+
+func fromSQSAttributes(attributes map[string]*sqs.MessageAttributeValue) map[string]string {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	out := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		out[k] = aws.StringValue(v.StringValue)
+	}
+	return out
+}
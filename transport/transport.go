@@ -0,0 +1,72 @@
+// Package transport abstracts the queue backend that notify.Notifications
+// sends and receives messages through. It was split out of the package so
+// that consumers who don't run on SQS (or who just want to unit test
+// without hitting AWS) have somewhere to plug in.
+package transport
+
+import (
+	"context"
+	"time"
+)
+
+// ScheduledAttribute is the RawMessage attribute key notify.Notifications
+// uses to mark a message that hasn't come due yet. It's shared with this
+// package so a Scanner implementation (see sqs.go's Scan) can filter on it
+// directly instead of surfacing arbitrary in-flight messages.
+const ScheduledAttribute = "NotifyScheduledAt"
+
+// RawMessage is an undelivered message along with whatever attributes its
+// transport attached. Ack/Nack are called back on the Transport that
+// produced it, which finds its own receipt through the unexported handle.
+type RawMessage struct {
+	Body       []byte
+	Attributes map[string]string
+
+	// handle is opaque state a Transport implementation stashes on a
+	// message so its own Ack/Nack can find the right underlying
+	// receipt/ack handle later (an SQS receipt handle, a *nats.Msg,
+	// etc). Only the Transport that produced a RawMessage should read
+	// it.
+	handle interface{}
+}
+
+// Transport moves message bytes between processes. Notifications is built
+// against this interface rather than any one backend, so alternative
+// implementations (see sqs.go, nats.go, memory.go in this package) can be
+// swapped in via New.
+type Transport interface {
+	// Publish sends body, along with any attributes, to the transport's
+	// destination.
+	Publish(ctx context.Context, body []byte, attributes map[string]string) error
+
+	// Subscribe returns a channel of messages. It may be called more
+	// than once by a single Notifications (e.g. ReceiveReader subscribes
+	// independently of Receive and Start); implementations that can't
+	// support multiple subscribers should document that restriction.
+	Subscribe(ctx context.Context) (<-chan RawMessage, error)
+
+	// Ack marks message as successfully handled so it isn't redelivered.
+	Ack(message RawMessage) error
+
+	// Nack puts message back for redelivery, e.g. because it wasn't due
+	// yet or its handler failed.
+	Nack(message RawMessage) error
+}
+
+// DelayedTransport is implemented by transports that support native
+// delayed delivery. Notifications.Send uses it when available and the
+// requested delay fits, falling back to attribute-tagged messages and the
+// AtSender loop otherwise.
+type DelayedTransport interface {
+	Transport
+	PublishAt(ctx context.Context, body []byte, attributes map[string]string, delay time.Duration) error
+}
+
+// Scanner is implemented by transports that can enumerate currently queued
+// messages without consuming them for delivery. AtSender relies on this to
+// find scheduled messages that have come due; transports that support a
+// large enough native delay on their own (or aren't meant to back
+// long-lived schedules) can leave it unimplemented.
+type Scanner interface {
+	Scan(ctx context.Context, max int) ([]RawMessage, error)
+}